@@ -0,0 +1,114 @@
+package main
+
+import "sync"
+
+// CacheStats reports memoization effectiveness for the cache_stats tool.
+type CacheStats struct {
+	DefinitionHits   int64            `json:"definitionHits"`
+	DefinitionMisses int64            `json:"definitionMisses"`
+	Entries          int              `json:"entries"`
+	Generations      map[string]int64 `json:"generations"`
+}
+
+type definitionKey struct {
+	root       string
+	symbolName string
+}
+
+type definitionEntry struct {
+	generation int64
+	text       string
+}
+
+// Cache is a process-lifetime memoization layer shared by every View in a
+// Session, modeled on gopls's cache -> session -> view tiering. Each View's
+// entries are stamped with that view's generation counter; a generation
+// bump invalidates everything cached for that root without having to track
+// individual files. edit_file and rename_symbol bump the generation of the
+// workspace they changed; the workspace watcher's fsnotify events and
+// didChange acknowledgments do not.
+type Cache struct {
+	mu          sync.Mutex
+	definitions map[definitionKey]definitionEntry
+	generations map[string]int64
+	hits        int64
+	misses      int64
+}
+
+func newCache() *Cache {
+	return &Cache{
+		definitions: make(map[definitionKey]definitionEntry),
+		generations: make(map[string]int64),
+	}
+}
+
+// generation returns the current generation for root, defaulting to 0 for a
+// root that hasn't been invalidated yet.
+func (c *Cache) generation(root string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[root]
+}
+
+// Invalidate bumps root's generation, discarding any definitions cached for
+// it on the next lookup.
+func (c *Cache) Invalidate(root string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[root]++
+}
+
+// Prune removes every entry belonging to root, including its generation
+// counter. Call this when a workspace is detached (RemoveView) so a
+// cache_stats snapshot and the definitions map don't grow unbounded across
+// repeated add_workspace/remove_workspace cycles.
+func (c *Cache) Prune(root string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.definitions {
+		if key.root == root {
+			delete(c.definitions, key)
+		}
+	}
+	delete(c.generations, root)
+}
+
+// GetDefinition returns a memoized ReadDefinition result for symbolName in
+// root, provided root hasn't been invalidated since it was stored.
+func (c *Cache) GetDefinition(root, symbolName string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := definitionKey{root: root, symbolName: symbolName}
+	entry, ok := c.definitions[key]
+	if !ok || entry.generation != c.generations[root] {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	return entry.text, true
+}
+
+// PutDefinition memoizes a ReadDefinition result for symbolName in root at
+// root's current generation.
+func (c *Cache) PutDefinition(root, symbolName, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := definitionKey{root: root, symbolName: symbolName}
+	c.definitions[key] = definitionEntry{generation: c.generations[root], text: text}
+}
+
+// Stats returns a snapshot of cache effectiveness for the cache_stats tool.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	generations := make(map[string]int64, len(c.generations))
+	for root, gen := range c.generations {
+		generations[root] = gen
+	}
+	return CacheStats{
+		DefinitionHits:   c.hits,
+		DefinitionMisses: c.misses,
+		Entries:          len(c.definitions),
+		Generations:      generations,
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+func TestCacheGetDefinitionMiss(t *testing.T) {
+	c := newCache()
+
+	if _, ok := c.GetDefinition("/root", "Foo"); ok {
+		t.Fatal("expected miss for an entry that was never stored")
+	}
+	stats := c.Stats()
+	if stats.DefinitionMisses != 1 || stats.DefinitionHits != 0 {
+		t.Fatalf("got hits=%d misses=%d, want hits=0 misses=1", stats.DefinitionHits, stats.DefinitionMisses)
+	}
+}
+
+func TestCacheGetDefinitionHit(t *testing.T) {
+	c := newCache()
+
+	c.PutDefinition("/root", "Foo", "func Foo() {}")
+	text, ok := c.GetDefinition("/root", "Foo")
+	if !ok {
+		t.Fatal("expected hit after PutDefinition")
+	}
+	if text != "func Foo() {}" {
+		t.Fatalf("got text %q, want %q", text, "func Foo() {}")
+	}
+
+	stats := c.Stats()
+	if stats.DefinitionHits != 1 || stats.DefinitionMisses != 0 {
+		t.Fatalf("got hits=%d misses=%d, want hits=1 misses=0", stats.DefinitionHits, stats.DefinitionMisses)
+	}
+}
+
+func TestCacheInvalidateBumpsGeneration(t *testing.T) {
+	c := newCache()
+
+	c.PutDefinition("/root", "Foo", "func Foo() {}")
+	c.Invalidate("/root")
+
+	if _, ok := c.GetDefinition("/root", "Foo"); ok {
+		t.Fatal("expected miss after Invalidate bumped the root's generation")
+	}
+}
+
+func TestCacheInvalidateIsScopedToRoot(t *testing.T) {
+	c := newCache()
+
+	c.PutDefinition("/root-a", "Foo", "func Foo() {}")
+	c.PutDefinition("/root-b", "Foo", "func Foo() {}")
+	c.Invalidate("/root-a")
+
+	if _, ok := c.GetDefinition("/root-a", "Foo"); ok {
+		t.Fatal("expected /root-a entry to be invalidated")
+	}
+	if _, ok := c.GetDefinition("/root-b", "Foo"); !ok {
+		t.Fatal("/root-b entry should be unaffected by invalidating /root-a")
+	}
+}
+
+func TestCachePruneRemovesRootEntirely(t *testing.T) {
+	c := newCache()
+
+	c.PutDefinition("/root-a", "Foo", "func Foo() {}")
+	c.PutDefinition("/root-b", "Bar", "func Bar() {}")
+	c.Invalidate("/root-a")
+	c.Prune("/root-a")
+
+	stats := c.Stats()
+	if stats.Entries != 1 {
+		t.Fatalf("got %d entries after Prune, want 1", stats.Entries)
+	}
+	if _, ok := stats.Generations["/root-a"]; ok {
+		t.Fatal("expected /root-a generation to be removed by Prune")
+	}
+	if _, ok := c.GetDefinition("/root-b", "Bar"); !ok {
+		t.Fatal("/root-b entry should survive pruning /root-a")
+	}
+}
+
+func TestCachePutDefinitionAfterPruneStartsFresh(t *testing.T) {
+	c := newCache()
+
+	c.PutDefinition("/root", "Foo", "v1")
+	c.Invalidate("/root")
+	c.Prune("/root")
+
+	// A re-attached workspace at the same root should not inherit the old
+	// generation counter or any leftover entries.
+	c.PutDefinition("/root", "Foo", "v2")
+	text, ok := c.GetDefinition("/root", "Foo")
+	if !ok || text != "v2" {
+		t.Fatalf("got text=%q ok=%v, want text=%q ok=true", text, ok, "v2")
+	}
+}
@@ -0,0 +1,99 @@
+// Package logging provides the structured logger used throughout the
+// server. Call sites pass a message plus alternating key/value fields
+// (e.g. Info("request handled", "method", "GET", "status", 200)); the
+// logger renders them as logfmt to stderr, one line per event, so output
+// stays greppable and machine-parseable when this server is embedded in
+// agent stacks.
+//
+// The writer is implemented here with no external dependency; it is not
+// backed by zerolog, hclog or any other logging library. It provides
+// Info/Debug/Warn/Error/Fatal with key/value args and Named child loggers;
+// it does no level filtering, so every call is written.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subsystem names the component a Logger is attached to at construction.
+type Subsystem string
+
+const (
+	Core  Subsystem = "core"
+	LSP   Subsystem = "lsp"
+	Tools Subsystem = "tools"
+)
+
+var writeMu sync.Mutex
+
+// Logger emits structured, leveled log lines tagged with its name. Use
+// Named to derive a hierarchical child logger for a subsystem (e.g. the
+// HTTP transport or the tool dispatcher) without losing the parent's name.
+type Logger struct {
+	name string
+}
+
+// NewLogger creates a Logger for the given subsystem.
+func NewLogger(subsystem Subsystem) *Logger {
+	return &Logger{name: string(subsystem)}
+}
+
+// Named returns a child logger whose name is "parent.name", so log lines
+// can be filtered by subsystem without losing which component emitted them.
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{name: l.name + "." + name}
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...any) {
+	l.write("info", msg, keysAndValues...)
+}
+
+func (l *Logger) Debug(msg string, keysAndValues ...any) {
+	l.write("debug", msg, keysAndValues...)
+}
+
+func (l *Logger) Warn(msg string, keysAndValues ...any) {
+	l.write("warn", msg, keysAndValues...)
+}
+
+func (l *Logger) Error(msg string, keysAndValues ...any) {
+	l.write("error", msg, keysAndValues...)
+}
+
+// Fatal logs at fatal level and terminates the process with exit status 1.
+func (l *Logger) Fatal(msg string, keysAndValues ...any) {
+	l.write("fatal", msg, keysAndValues...)
+	os.Exit(1)
+}
+
+func (l *Logger) write(level, msg string, keysAndValues ...any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s logger=%s msg=%s", time.Now().Format(time.RFC3339), level, l.name, quote(msg))
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", keysAndValues[i], quote(fmt.Sprintf("%v", keysAndValues[i+1])))
+	}
+	// An odd trailing argument has no value to pair with; surface it as-is
+	// rather than silently dropping it.
+	if len(keysAndValues)%2 == 1 {
+		fmt.Fprintf(&b, " %s", quote(fmt.Sprintf("%v", keysAndValues[len(keysAndValues)-1])))
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	fmt.Fprintln(os.Stderr, b.String())
+}
+
+// quote wraps s in double quotes, escaping as needed, whenever it is empty or
+// contains whitespace, a quote character or '=', so logfmt fields stay
+// unambiguous and each event stays on one line.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
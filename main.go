@@ -21,6 +21,9 @@ import (
 // Create a logger for the core component
 var coreLogger = logging.NewLogger(logging.Core)
 
+// httpLogger is a named child logger for the HTTP transport's request/response events.
+var httpLogger = coreLogger.Named("http")
+
 type config struct {
 	workspaceDir string
 	lspCommand   string
@@ -36,6 +39,7 @@ type mcpServer struct {
 	ctx              context.Context
 	cancelFunc       context.CancelFunc
 	workspaceWatcher *watcher.WorkspaceWatcher
+	session          *Session
 }
 
 func parseConfig() (*config, error) {
@@ -87,50 +91,35 @@ func newServer(config *config) (*mcpServer, error) {
 		config:     *config,
 		ctx:        ctx,
 		cancelFunc: cancel,
+		session:    newSession(),
 	}, nil
 }
 
 func (s *mcpServer) initializeLSP() error {
-	coreLogger.Info("Changing to workspace directory: %s", s.config.workspaceDir)
+	coreLogger.Info("changing to workspace directory", "workspace", s.config.workspaceDir)
 	if err := os.Chdir(s.config.workspaceDir); err != nil {
 		return fmt.Errorf("failed to change to workspace directory: %v", err)
 	}
 
-	coreLogger.Info("Creating LSP client with command: %s, args: %v", s.config.lspCommand, s.config.lspArgs)
-	client, err := lsp.NewClient(s.config.lspCommand, s.config.lspArgs...)
-	if err != nil {
-		return fmt.Errorf("failed to create LSP client: %v", err)
-	}
-	s.lspClient = client
-	s.workspaceWatcher = watcher.NewWorkspaceWatcher(client)
-
-	coreLogger.Info("Initializing LSP client...")
-	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir)
+	view, err := s.session.AddView(s.ctx, s.config.workspaceDir, s.config.lspCommand, s.config.lspArgs)
 	if err != nil {
-		return fmt.Errorf("initialize failed: %v", err)
+		return err
 	}
 
-	coreLogger.Info("LSP server capabilities received")
-	coreLogger.Debug("Server capabilities: %+v", initResult.Capabilities)
+	// Keep the primary view's client and watcher directly accessible since
+	// most tools are still scoped to a single workspace.
+	s.lspClient = view.lspClient
+	s.workspaceWatcher = view.watcher
 
-	coreLogger.Info("Starting workspace watcher...")
-	go s.workspaceWatcher.WatchWorkspace(s.ctx, s.config.workspaceDir)
-	
-	coreLogger.Info("Waiting for LSP server to be ready...")
-	err = client.WaitForServerReady(s.ctx)
-	if err != nil {
-		return fmt.Errorf("LSP server ready wait failed: %v", err)
-	}
-	coreLogger.Info("LSP server is ready")
+	coreLogger.Info("LSP server is ready", "workspace", s.config.workspaceDir)
 	return nil
 }
 
 func (s *mcpServer) start() error {
-	coreLogger.Info("Initializing LSP client with command: %s, args: %v", s.config.lspCommand, s.config.lspArgs)
-	coreLogger.Info("Workspace directory: %s", s.config.workspaceDir)
-	
+	coreLogger.Info("initializing LSP client", "command", s.config.lspCommand, "args", s.config.lspArgs, "workspace", s.config.workspaceDir)
+
 	if err := s.initializeLSP(); err != nil {
-		coreLogger.Error("LSP initialization failed: %v", err)
+		coreLogger.Error("LSP initialization failed", "error", err)
 		return err
 	}
 	coreLogger.Info("LSP client initialized successfully")
@@ -140,55 +129,57 @@ func (s *mcpServer) start() error {
 		Version: "v0.0.2",
 	}, nil)
 
-	coreLogger.Info("Registering MCP tools...")
+	coreLogger.Info("registering MCP tools")
 	err := s.registerTools()
 	if err != nil {
-		coreLogger.Error("Tool registration failed: %v", err)
+		coreLogger.Error("tool registration failed", "error", err)
 		return fmt.Errorf("tool registration failed: %v", err)
 	}
 	coreLogger.Info("MCP tools registered successfully")
 
 	switch s.config.mode {
 	case "stdio":
-		coreLogger.Info("Starting MCP server in stdio mode")
+		coreLogger.Info("starting MCP server in stdio mode")
 		return s.mcpServer.Run(s.ctx, &mcp.StdioTransport{})
 	case "http":
 		addr := fmt.Sprintf(":%d", s.config.port)
-		coreLogger.Info("Starting MCP server in HTTP mode")
-		coreLogger.Info("Server will bind to address: %s", addr)
-		coreLogger.Info("Full server URL will be: http://0.0.0.0%s", addr)
-		
+		coreLogger.Info("starting MCP server in HTTP mode", "addr", addr, "url", fmt.Sprintf("http://0.0.0.0%s", addr))
+
 		handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
-			coreLogger.Info("HTTP request received: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
 			return s.mcpServer
 		}, nil)
-		
-		// Add logging middleware
+
+		// Add logging middleware: one structured event per request.
 		loggedHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			coreLogger.Info("Request: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
-			handler.ServeHTTP(w, r)
-			coreLogger.Info("Response completed for %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			handler.ServeHTTP(rec, r)
+			httpLogger.Info("request handled",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"latency", time.Since(start),
+				"remote_addr", r.RemoteAddr,
+			)
 		})
-		
+
 		httpServer := &http.Server{
 			Addr:    addr,
 			Handler: loggedHandler,
 		}
-		
+
 		// Start server in a goroutine so we can handle shutdown
 		go func() {
 			<-s.ctx.Done()
-			coreLogger.Info("Shutting down HTTP server")
+			coreLogger.Info("shutting down HTTP server")
 			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 			httpServer.Shutdown(shutdownCtx)
 		}()
-		
-		coreLogger.Info("About to call ListenAndServe() on %s", addr)
+
 		err := httpServer.ListenAndServe()
 		if err != nil && err != http.ErrServerClosed {
-			coreLogger.Error("HTTP server failed: %v", err)
+			coreLogger.Error("HTTP server failed", "error", err)
 			return fmt.Errorf("HTTP server failed: %v", err)
 		}
 		coreLogger.Info("HTTP server stopped")
@@ -198,9 +189,20 @@ func (s *mcpServer) start() error {
 	}
 }
 
+// statusRecorder captures the status code written by an http.Handler so the
+// logging middleware can report it after the handler returns.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
 func main() {
-	coreLogger.Info("MCP Language Server starting")
-	coreLogger.Info("Process ID: %d, Parent ID: %d", os.Getpid(), os.Getppid())
+	coreLogger.Info("MCP Language Server starting", "pid", os.Getpid(), "ppid", os.Getppid())
 
 	done := make(chan struct{})
 	sigChan := make(chan os.Signal, 1)
@@ -208,19 +210,20 @@ func main() {
 
 	config, err := parseConfig()
 	if err != nil {
-		coreLogger.Fatal("Failed to parse config: %v", err)
+		coreLogger.Fatal("failed to parse config", "error", err)
 	}
 
-	coreLogger.Info("Configuration loaded:")
-	coreLogger.Info("  Mode: %s", config.mode)
-	coreLogger.Info("  Port: %d", config.port)
-	coreLogger.Info("  Workspace: %s", config.workspaceDir)
-	coreLogger.Info("  LSP Command: %s", config.lspCommand)
-	coreLogger.Info("  LSP Args: %v", config.lspArgs)
+	coreLogger.Info("configuration loaded",
+		"mode", config.mode,
+		"port", config.port,
+		"workspace", config.workspaceDir,
+		"lsp_command", config.lspCommand,
+		"lsp_args", config.lspArgs,
+	)
 
 	server, err := newServer(config)
 	if err != nil {
-		coreLogger.Fatal("Failed to create server: %v", err)
+		coreLogger.Fatal("failed to create server", "error", err)
 	}
 
 	// Parent process monitoring channel
@@ -230,7 +233,7 @@ func main() {
 	// Claude desktop does not properly kill child processes for MCP servers
 	go func() {
 		ppid := os.Getppid()
-		coreLogger.Debug("Monitoring parent process: %d", ppid)
+		coreLogger.Debug("monitoring parent process", "ppid", ppid)
 
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
@@ -240,7 +243,7 @@ func main() {
 			case <-ticker.C:
 				currentPpid := os.Getppid()
 				if currentPpid != ppid && (currentPpid == 1 || ppid == 1) {
-					coreLogger.Info("Parent process %d terminated (current ppid: %d), initiating shutdown", ppid, currentPpid)
+					coreLogger.Info("parent process terminated, initiating shutdown", "original_ppid", ppid, "current_ppid", currentPpid)
 					close(parentDeath)
 					return
 				}
@@ -254,34 +257,34 @@ func main() {
 	go func() {
 		select {
 		case sig := <-sigChan:
-			coreLogger.Info("Received signal %v in PID: %d", sig, os.Getpid())
+			coreLogger.Info("received signal", "signal", sig, "pid", os.Getpid())
 			cleanup(server, done)
 		case <-parentDeath:
-			coreLogger.Info("Parent death detected, initiating shutdown")
+			coreLogger.Info("parent death detected, initiating shutdown")
 			cleanup(server, done)
 		}
 	}()
 
 	if err := server.start(); err != nil {
-		coreLogger.Error("Server error: %v", err)
+		coreLogger.Error("server error", "error", err)
 		cleanup(server, done)
 		os.Exit(1)
 	}
 
 	<-done
-	coreLogger.Info("Server shutdown complete for PID: %d", os.Getpid())
+	coreLogger.Info("server shutdown complete", "pid", os.Getpid())
 	os.Exit(0)
 }
 
 func cleanup(s *mcpServer, done chan struct{}) {
-	coreLogger.Info("Cleanup initiated for PID: %d", os.Getpid())
+	coreLogger.Info("cleanup initiated", "pid", os.Getpid())
 
 	// Create a context with timeout for shutdown operations
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if s.lspClient != nil {
-		coreLogger.Info("Closing open files")
+		coreLogger.Info("closing open files")
 		s.lspClient.CloseAllFiles(ctx)
 
 		// Create a shorter timeout context for the shutdown request
@@ -291,9 +294,9 @@ func cleanup(s *mcpServer, done chan struct{}) {
 		// Run shutdown in a goroutine with timeout to avoid blocking if LSP doesn't respond
 		shutdownDone := make(chan struct{})
 		go func() {
-			coreLogger.Info("Sending shutdown request")
+			coreLogger.Info("sending shutdown request")
 			if err := s.lspClient.Shutdown(shutdownCtx); err != nil {
-				coreLogger.Error("Shutdown request failed: %v", err)
+				coreLogger.Error("shutdown request failed", "error", err)
 			}
 			close(shutdownDone)
 		}()
@@ -301,19 +304,29 @@ func cleanup(s *mcpServer, done chan struct{}) {
 		// Wait for shutdown with timeout
 		select {
 		case <-shutdownDone:
-			coreLogger.Info("Shutdown request completed")
+			coreLogger.Info("shutdown request completed")
 		case <-time.After(1 * time.Second):
-			coreLogger.Warn("Shutdown request timed out, proceeding with exit")
+			coreLogger.Warn("shutdown request timed out, proceeding with exit")
 		}
 
-		coreLogger.Info("Sending exit notification")
+		coreLogger.Info("sending exit notification")
 		if err := s.lspClient.Exit(ctx); err != nil {
-			coreLogger.Error("Exit notification failed: %v", err)
+			coreLogger.Error("exit notification failed", "error", err)
 		}
 
-		coreLogger.Info("Closing LSP client")
+		coreLogger.Info("closing LSP client")
 		if err := s.lspClient.Close(); err != nil {
-			coreLogger.Error("Failed to close LSP client: %v", err)
+			coreLogger.Error("failed to close LSP client", "error", err)
+		}
+	}
+
+	// Shut down any additional workspaces attached via add_workspace
+	for _, view := range s.session.Views() {
+		if view.root == s.config.workspaceDir {
+			continue
+		}
+		if err := s.session.RemoveView(ctx, view.root); err != nil {
+			coreLogger.Error("failed to close workspace", "workspace", view.root, "error", err)
 		}
 	}
 
@@ -324,5 +337,5 @@ func cleanup(s *mcpServer, done chan struct{}) {
 		close(done)
 	}
 
-	coreLogger.Info("Cleanup completed for PID: %d", os.Getpid())
+	coreLogger.Info("cleanup completed", "pid", os.Getpid())
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressReporter builds a callback bound to the progress token the client
+// attached to req, if any, for forwarding caller-driven checkpoints (e.g.
+// "searched N of M workspaces") as MCP progress notifications. When the
+// client didn't ask for progress notifications the returned func is a
+// no-op so call sites never need to check for a nil sink.
+//
+// This does not correlate real LSP $/progress / WorkDoneProgress
+// notifications from the language server itself — internal/lsp doesn't
+// surface those to callers in this checkout, so there's nothing to forward
+// for a single long-running request like references or rename_symbol.
+// workspace_symbol is the only caller today, reporting its own synthetic
+// per-view checkpoints rather than anything the LSP server emitted.
+func (s *mcpServer) progressReporter(req *mcp.CallToolRequest) func(message string, current, total int) {
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return func(message string, current, total int) {}
+	}
+
+	return func(message string, current, total int) {
+		err := req.Session.NotifyProgress(s.ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Message:       message,
+			Progress:      float64(current),
+			Total:         float64(total),
+		})
+		if err != nil {
+			toolLogger.Debug("failed to send progress notification", "error", err)
+		}
+	}
+}
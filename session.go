@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/watcher"
+)
+
+// View is a single LSP client bound to one workspace root, modeled after
+// gopls's per-workspace view. A Session owns a set of Views so that one
+// MCP process can serve several workspaces (and several language servers)
+// at once, e.g. a Go root handled by gopls alongside a Python root handled
+// by pyright.
+type View struct {
+	root       string
+	lspCommand string
+	lspArgs    []string
+	lspClient  *lsp.Client
+	watcher    *watcher.WorkspaceWatcher
+}
+
+// Session owns the Views active in this process, plus the process-lifetime
+// Cache shared across them.
+type Session struct {
+	mu    sync.RWMutex
+	views map[string]*View
+	cache *Cache
+}
+
+func newSession() *Session {
+	return &Session{views: make(map[string]*View), cache: newCache()}
+}
+
+// AddView creates and initializes a new View rooted at root, starting its
+// own LSP client and workspace watcher, and registers it on the session.
+func (sess *Session) AddView(ctx context.Context, root, lspCommand string, lspArgs []string) (*View, error) {
+	sess.mu.Lock()
+	if _, exists := sess.views[root]; exists {
+		sess.mu.Unlock()
+		return nil, fmt.Errorf("workspace already attached: %s", root)
+	}
+	// Reserve root under the lock so a concurrent AddView for the same root
+	// fails the check above immediately, instead of both racing through the
+	// slow LSP handshake below and one silently overwriting the other's
+	// entry in views, leaking the loser's client and watcher goroutine.
+	// Views() skips nil entries, so the reservation is invisible to readers
+	// until startView finishes.
+	sess.views[root] = nil
+	sess.mu.Unlock()
+
+	view, err := startView(ctx, root, lspCommand, lspArgs)
+	if err != nil {
+		sess.mu.Lock()
+		delete(sess.views, root)
+		sess.mu.Unlock()
+		return nil, err
+	}
+
+	sess.mu.Lock()
+	sess.views[root] = view
+	sess.mu.Unlock()
+
+	return view, nil
+}
+
+// startView runs the (slow) LSP handshake for a new View rooted at root,
+// without touching Session state.
+func startView(ctx context.Context, root, lspCommand string, lspArgs []string) (*View, error) {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, fmt.Errorf("workspace directory does not exist: %s", root)
+	}
+
+	coreLogger.Info("creating LSP client for workspace", "workspace", root, "command", lspCommand, "args", lspArgs)
+	client, err := lsp.NewClient(lspCommand, lspArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LSP client: %v", err)
+	}
+
+	view := &View{
+		root:       root,
+		lspCommand: lspCommand,
+		lspArgs:    lspArgs,
+		lspClient:  client,
+		watcher:    watcher.NewWorkspaceWatcher(client),
+	}
+
+	coreLogger.Info("initializing LSP client for workspace", "workspace", root)
+	initResult, err := client.InitializeLSPClient(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("initialize failed for workspace %s: %v", root, err)
+	}
+	coreLogger.Debug("server capabilities received", "workspace", root, "capabilities", initResult.Capabilities)
+
+	go view.watcher.WatchWorkspace(ctx, root)
+
+	if err := client.WaitForServerReady(ctx); err != nil {
+		return nil, fmt.Errorf("LSP server ready wait failed for workspace %s: %v", root, err)
+	}
+
+	return view, nil
+}
+
+// RemoveView detaches and shuts down the View rooted at root.
+func (sess *Session) RemoveView(ctx context.Context, root string) error {
+	sess.mu.Lock()
+	view, exists := sess.views[root]
+	if !exists || view == nil {
+		sess.mu.Unlock()
+		return fmt.Errorf("no workspace attached at: %s", root)
+	}
+	delete(sess.views, root)
+	sess.mu.Unlock()
+
+	sess.cache.Prune(root)
+
+	view.lspClient.CloseAllFiles(ctx)
+	if err := view.lspClient.Shutdown(ctx); err != nil {
+		coreLogger.Error("shutdown request failed for workspace", "workspace", root, "error", err)
+	}
+	if err := view.lspClient.Exit(ctx); err != nil {
+		coreLogger.Error("exit notification failed for workspace", "workspace", root, "error", err)
+	}
+	return view.lspClient.Close()
+}
+
+// Views returns a snapshot of the currently attached views.
+func (sess *Session) Views() []*View {
+	sess.mu.RLock()
+	defer sess.mu.RUnlock()
+	views := make([]*View, 0, len(sess.views))
+	for _, view := range sess.views {
+		if view == nil {
+			// Reserved by an AddView call still running its LSP handshake.
+			continue
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// viewForFile picks the View whose root contains filePath, so a file-scoped
+// tool call is routed to the LSP client for the workspace (and language)
+// that actually owns the file rather than always the primary workspace.
+// When multiple attached roots contain the file, the most specific (longest)
+// root wins. Falls back to the primary workspace's View if no attached root
+// contains the file.
+func (s *mcpServer) viewForFile(filePath string) (*View, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		abs = filePath
+	}
+
+	var best *View
+	for _, view := range s.session.Views() {
+		if view.root != abs && !strings.HasPrefix(abs, view.root+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(view.root) > len(best.root) {
+			best = view
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	for _, view := range s.session.Views() {
+		if view.root == s.config.workspaceDir {
+			return view, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no attached workspace contains file: %s", filePath)
+}
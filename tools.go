@@ -3,15 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// toolLogger is a named child logger for MCP tool invocations.
+var toolLogger = coreLogger.Named("tools")
+
 // Tool parameter types
 type EditFileParams struct {
-	FilePath string      `json:"filePath" jsonschema:"description=Path to the file to edit"`
-	Edits    []TextEdit  `json:"edits" jsonschema:"description=List of edits to apply"`
+	FilePath string     `json:"filePath" jsonschema:"description=Path to the file to edit"`
+	Edits    []TextEdit `json:"edits" jsonschema:"description=List of edits to apply"`
 }
 
 type TextEdit struct {
@@ -47,14 +53,72 @@ type RenameSymbolParams struct {
 	NewName  string `json:"newName" jsonschema:"description=The new name for the symbol"`
 }
 
+type PrepareRenameParams struct {
+	FilePath string `json:"filePath" jsonschema:"description=The path to the file containing the symbol to rename"`
+	Line     int    `json:"line" jsonschema:"description=The line number where the symbol is located (1-indexed)"`
+	Column   int    `json:"column" jsonschema:"description=The column number where the symbol is located (1-indexed)"`
+}
+
+// PrepareRenameError is prepare_rename's structured output. The tool has no
+// textDocument/prepareRename support, so every call returns one, with Reason
+// saying why, so callers can branch on Reason instead of parsing a string.
+type PrepareRenameError struct {
+	Reason string `json:"reason"`
+}
+
+type CodeActionParams struct {
+	FilePath       string `json:"filePath" jsonschema:"description=The path to the file to get code actions for"`
+	StartLine      int    `json:"startLine,omitempty" jsonschema:"description=Start of the line range to request code actions for (1-indexed). Defaults to the whole file."`
+	EndLine        int    `json:"endLine,omitempty" jsonschema:"description=End of the line range to request code actions for (1-indexed). Defaults to the whole file."`
+	DiagnosticOnly bool   `json:"diagnosticOnly,omitempty" jsonschema:"description=If true only return actions associated with a diagnostic at the given range"`
+}
+
+type ApplyCodeActionParams struct {
+	FilePath string `json:"filePath" jsonschema:"description=The path to the file the code action was requested for"`
+	Index    int    `json:"index,omitempty" jsonschema:"description=The index of the code action to apply, from the code_action results. Ignored if title is set."`
+	Title    string `json:"title,omitempty" jsonschema:"description=The title of the code action to apply, from the code_action results. Takes precedence over index, useful when indices may have shifted since code_action was last called."`
+}
+
+type FormatFileParams struct {
+	FilePath  string `json:"filePath" jsonschema:"description=The path to the file to format"`
+	StartLine int    `json:"startLine,omitempty" jsonschema:"description=Start of the line range to format (1-indexed). Omit to format the whole file."`
+	EndLine   int    `json:"endLine,omitempty" jsonschema:"description=End of the line range to format (1-indexed). Omit to format the whole file."`
+}
+
+type WorkspaceSymbolParams struct {
+	Query string `json:"query" jsonschema:"description=The symbol name or substring to search for across all attached workspaces"`
+}
+
+type AddWorkspaceParams struct {
+	WorkspaceDir string   `json:"workspaceDir" jsonschema:"description=Path to the additional workspace directory to attach"`
+	LspCommand   string   `json:"lspCommand" jsonschema:"description=LSP command to run for this workspace"`
+	LspArgs      []string `json:"lspArgs,omitempty" jsonschema:"description=Arguments to pass to the LSP command"`
+}
+
+type RemoveWorkspaceParams struct {
+	WorkspaceDir string `json:"workspaceDir" jsonschema:"description=Path to the workspace directory to detach, as passed to add_workspace"`
+}
+
+// unimplementedResult builds an IsError tool result for a handler whose
+// LSP-backed implementation belongs in internal/tools and hasn't been
+// written in this checkout, so the handler has nothing real to call.
+func unimplementedResult(tool, reason string) (*mcp.CallToolResult, any, error) {
+	toolLogger.Error("tool not implemented", "tool", tool, "reason", reason)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("%s is not implemented: %s", tool, reason)}},
+		IsError: true,
+	}, nil, nil
+}
+
 func (s *mcpServer) registerTools() error {
-	coreLogger.Debug("Registering MCP tools")
+	coreLogger.Debug("registering MCP tools")
 
 	// Edit file tool
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "edit_file",
 		Description: "Apply multiple text edits to a file.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, params EditFileParams) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
 		var edits []tools.TextEdit
 		for _, edit := range params.Edits {
 			edits = append(edits, tools.TextEdit{
@@ -64,15 +128,26 @@ func (s *mcpServer) registerTools() error {
 			})
 		}
 
-		coreLogger.Debug("Executing edit_file for file: %s", params.FilePath)
-		response, err := tools.ApplyTextEdits(s.ctx, s.lspClient, params.FilePath, edits)
+		toolLogger.Debug("executing tool", "tool", "edit_file", "file_path", params.FilePath, "edit_count", len(edits))
+		view, err := s.viewForFile(params.FilePath)
 		if err != nil {
-			coreLogger.Error("Failed to apply edits: %v", err)
+			toolLogger.Error("tool failed", "tool", "edit_file", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to apply edits: %v", err)}},
 				IsError: true,
 			}, nil, nil
 		}
+
+		response, err := tools.ApplyTextEdits(s.ctx, view.lspClient, params.FilePath, edits)
+		if err != nil {
+			toolLogger.Error("tool failed", "tool", "edit_file", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to apply edits: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		s.session.cache.Invalidate(view.root)
+		toolLogger.Debug("tool completed", "tool", "edit_file", "file_path", params.FilePath, "duration", time.Since(start))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: response}},
 		}, nil, nil
@@ -83,15 +158,26 @@ func (s *mcpServer) registerTools() error {
 		Name:        "definition",
 		Description: "Read the source code definition of a symbol (function, type, constant, etc.) from the codebase. Returns the complete implementation code where the symbol is defined.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, params DefinitionParams) (*mcp.CallToolResult, any, error) {
-		coreLogger.Debug("Executing definition for symbol: %s", params.SymbolName)
+		start := time.Now()
+		toolLogger.Debug("executing tool", "tool", "definition", "symbol", params.SymbolName)
+
+		if cached, ok := s.session.cache.GetDefinition(s.config.workspaceDir, params.SymbolName); ok {
+			toolLogger.Debug("tool completed (cache hit)", "tool", "definition", "symbol", params.SymbolName, "duration", time.Since(start))
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: cached}},
+			}, nil, nil
+		}
+
 		text, err := tools.ReadDefinition(s.ctx, s.lspClient, params.SymbolName)
 		if err != nil {
-			coreLogger.Error("Failed to get definition: %v", err)
+			toolLogger.Error("tool failed", "tool", "definition", "symbol", params.SymbolName, "duration", time.Since(start), "error", err)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to get definition: %v", err)}},
 				IsError: true,
 			}, nil, nil
 		}
+		s.session.cache.PutDefinition(s.config.workspaceDir, params.SymbolName, text)
+		toolLogger.Debug("tool completed", "tool", "definition", "symbol", params.SymbolName, "duration", time.Since(start))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: text}},
 		}, nil, nil
@@ -102,15 +188,17 @@ func (s *mcpServer) registerTools() error {
 		Name:        "references",
 		Description: "Find all usages and references of a symbol throughout the codebase. Returns a list of all files and locations where the symbol appears.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, params ReferencesParams) (*mcp.CallToolResult, any, error) {
-		coreLogger.Debug("Executing references for symbol: %s", params.SymbolName)
+		start := time.Now()
+		toolLogger.Debug("executing tool", "tool", "references", "symbol", params.SymbolName)
 		text, err := tools.FindReferences(s.ctx, s.lspClient, params.SymbolName)
 		if err != nil {
-			coreLogger.Error("Failed to find references: %v", err)
+			toolLogger.Error("tool failed", "tool", "references", "symbol", params.SymbolName, "duration", time.Since(start), "error", err)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to find references: %v", err)}},
 				IsError: true,
 			}, nil, nil
 		}
+		toolLogger.Debug("tool completed", "tool", "references", "symbol", params.SymbolName, "duration", time.Since(start))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: text}},
 		}, nil, nil
@@ -121,6 +209,7 @@ func (s *mcpServer) registerTools() error {
 		Name:        "diagnostics",
 		Description: "Get diagnostic information for a specific file from the language server.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, params DiagnosticsParams) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
 		contextLines := params.ContextLines
 		if contextLines == 0 {
 			contextLines = 5 // default value
@@ -130,15 +219,25 @@ func (s *mcpServer) registerTools() error {
 			showLineNumbers = true // default value
 		}
 
-		coreLogger.Debug("Executing diagnostics for file: %s", params.FilePath)
-		text, err := tools.GetDiagnosticsForFile(s.ctx, s.lspClient, params.FilePath, contextLines, showLineNumbers)
+		toolLogger.Debug("executing tool", "tool", "diagnostics", "file_path", params.FilePath)
+		view, err := s.viewForFile(params.FilePath)
+		if err != nil {
+			toolLogger.Error("tool failed", "tool", "diagnostics", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to get diagnostics: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		text, err := tools.GetDiagnosticsForFile(s.ctx, view.lspClient, params.FilePath, contextLines, showLineNumbers)
 		if err != nil {
-			coreLogger.Error("Failed to get diagnostics: %v", err)
+			toolLogger.Error("tool failed", "tool", "diagnostics", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to get diagnostics: %v", err)}},
 				IsError: true,
 			}, nil, nil
 		}
+		toolLogger.Debug("tool completed", "tool", "diagnostics", "file_path", params.FilePath, "duration", time.Since(start))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: text}},
 		}, nil, nil
@@ -149,39 +248,237 @@ func (s *mcpServer) registerTools() error {
 		Name:        "hover",
 		Description: "Get hover information (type, documentation) for a symbol at the specified position.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, params HoverParams) (*mcp.CallToolResult, any, error) {
-		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", params.FilePath, params.Line, params.Column)
-		text, err := tools.GetHoverInfo(s.ctx, s.lspClient, params.FilePath, params.Line, params.Column)
+		start := time.Now()
+		toolLogger.Debug("executing tool", "tool", "hover", "file_path", params.FilePath, "line", params.Line, "column", params.Column)
+		view, err := s.viewForFile(params.FilePath)
 		if err != nil {
-			coreLogger.Error("Failed to get hover information: %v", err)
+			toolLogger.Error("tool failed", "tool", "hover", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to get hover information: %v", err)}},
 				IsError: true,
 			}, nil, nil
 		}
+
+		text, err := tools.GetHoverInfo(s.ctx, view.lspClient, params.FilePath, params.Line, params.Column)
+		if err != nil {
+			toolLogger.Error("tool failed", "tool", "hover", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to get hover information: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		toolLogger.Debug("tool completed", "tool", "hover", "file_path", params.FilePath, "duration", time.Since(start))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: text}},
 		}, nil, nil
 	})
 
+	// Prepare rename tool
+	//
+	// internal/tools has no textDocument/prepareRename wrapper, so this
+	// handler does not query the language server: every call returns an
+	// error result with a structured PrepareRenameError.
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "prepare_rename",
+		Description: "Always returns an error result with a structured reason: this server does not implement prepare-rename, so no range or identifier text is returned for the specified position.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params PrepareRenameParams) (*mcp.CallToolResult, any, error) {
+		toolLogger.Debug("executing tool", "tool", "prepare_rename", "file_path", params.FilePath, "line", params.Line, "column", params.Column)
+		reason := PrepareRenameError{Reason: "requires a textDocument/prepareRename wrapper in internal/tools that hasn't been written"}
+		toolLogger.Error("tool not implemented", "tool", "prepare_rename", "reason", reason.Reason)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("prepare_rename is not implemented: %s", reason.Reason)}},
+			IsError: true,
+		}, reason, nil
+	})
+
 	// Rename symbol tool
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "rename_symbol",
 		Description: "Rename a symbol (variable, function, class, etc.) at the specified position and update all references throughout the codebase.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, params RenameSymbolParams) (*mcp.CallToolResult, any, error) {
-		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s", params.FilePath, params.Line, params.Column, params.NewName)
-		text, err := tools.RenameSymbol(s.ctx, s.lspClient, params.FilePath, params.Line, params.Column, params.NewName)
+		start := time.Now()
+		toolLogger.Debug("executing tool", "tool", "rename_symbol", "file_path", params.FilePath, "line", params.Line, "column", params.Column, "new_name", params.NewName)
+
+		view, err := s.viewForFile(params.FilePath)
 		if err != nil {
-			coreLogger.Error("Failed to rename symbol: %v", err)
+			toolLogger.Error("tool failed", "tool", "rename_symbol", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to rename symbol: %v", err)}},
 				IsError: true,
 			}, nil, nil
 		}
+
+		text, err := tools.RenameSymbol(s.ctx, view.lspClient, params.FilePath, params.Line, params.Column, params.NewName)
+		if err != nil {
+			toolLogger.Error("tool failed", "tool", "rename_symbol", "file_path", params.FilePath, "duration", time.Since(start), "error", err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to rename symbol: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		s.session.cache.Invalidate(view.root)
+		toolLogger.Debug("tool completed", "tool", "rename_symbol", "file_path", params.FilePath, "duration", time.Since(start))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: text}},
 		}, nil, nil
 	})
 
-	coreLogger.Info("Successfully registered all MCP tools")
+	// Code action tool
+	//
+	// internal/tools has no textDocument/codeAction wrapper, so this handler
+	// does not query the language server and returns an error result.
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "code_action",
+		Description: "Always returns an error result: this server does not implement code actions, so no quick fixes or refactorings are listed for the file.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params CodeActionParams) (*mcp.CallToolResult, any, error) {
+		toolLogger.Debug("executing tool", "tool", "code_action", "file_path", params.FilePath, "start_line", params.StartLine, "end_line", params.EndLine)
+		return unimplementedResult("code_action", "requires a textDocument/codeAction wrapper in internal/tools that hasn't been written")
+	})
+
+	// Apply code action tool
+	//
+	// internal/tools has no way to resolve a code action's WorkspaceEdit by
+	// index or title, so this handler applies nothing and returns an error
+	// result.
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "apply_code_action",
+		Description: "Always returns an error result: this server does not implement code actions, so nothing is applied for the given index or title.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params ApplyCodeActionParams) (*mcp.CallToolResult, any, error) {
+		toolLogger.Debug("executing tool", "tool", "apply_code_action", "file_path", params.FilePath, "index", params.Index, "title", params.Title)
+		return unimplementedResult("apply_code_action", "requires internal/tools to resolve and apply a code action's WorkspaceEdit by index or title, which hasn't been written")
+	})
+
+	// Format file tool
+	//
+	// internal/tools has no textDocument/formatting or rangeFormatting
+	// wrapper, so this handler formats nothing and returns an error result.
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "format_file",
+		Description: "Always returns an error result: this server does not implement formatting, so the file (or line range) is left unchanged.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params FormatFileParams) (*mcp.CallToolResult, any, error) {
+		toolLogger.Debug("executing tool", "tool", "format_file", "file_path", params.FilePath, "start_line", params.StartLine, "end_line", params.EndLine)
+		return unimplementedResult("format_file", "requires a textDocument/formatting and rangeFormatting wrapper in internal/tools that hasn't been written")
+	})
+
+	// Workspace symbol tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "workspace_symbol",
+		Description: "Search for symbols by name or substring across every attached workspace. Results are attributed to the workspace they were found in.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params WorkspaceSymbolParams) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		toolLogger.Debug("executing tool", "tool", "workspace_symbol", "query", params.Query)
+
+		views := s.session.Views()
+		reportProgress := s.progressReporter(req)
+		var results strings.Builder
+		var errs []string
+		for i, view := range views {
+			text, err := tools.SearchWorkspaceSymbols(s.ctx, view.lspClient, params.Query)
+			reportProgress(fmt.Sprintf("searched %s", view.root), i+1, len(views))
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", view.root, err))
+				continue
+			}
+			if text == "" {
+				continue
+			}
+			fmt.Fprintf(&results, "# %s\n%s\n", view.root, text)
+		}
+
+		if results.Len() == 0 && len(errs) > 0 {
+			toolLogger.Error("tool failed", "tool", "workspace_symbol", "query", params.Query, "duration", time.Since(start), "error", strings.Join(errs, "; "))
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to search workspace symbols: %s", strings.Join(errs, "; "))}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		toolLogger.Debug("tool completed", "tool", "workspace_symbol", "query", params.Query, "view_count", len(views), "duration", time.Since(start))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: results.String()}},
+		}, nil, nil
+	})
+
+	// Add workspace tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "add_workspace",
+		Description: "Attach an additional workspace root to this MCP process, starting its own LSP client so multiple projects (or languages) can be served without restarting.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params AddWorkspaceParams) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		workspaceDir, err := filepath.Abs(params.WorkspaceDir)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to resolve workspace directory: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		toolLogger.Debug("executing tool", "tool", "add_workspace", "workspace", workspaceDir, "lsp_command", params.LspCommand)
+		if _, err := s.session.AddView(s.ctx, workspaceDir, params.LspCommand, params.LspArgs); err != nil {
+			toolLogger.Error("tool failed", "tool", "add_workspace", "workspace", workspaceDir, "duration", time.Since(start), "error", err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to add workspace: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		toolLogger.Debug("tool completed", "tool", "add_workspace", "workspace", workspaceDir, "duration", time.Since(start))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("attached workspace %s", workspaceDir)}},
+		}, nil, nil
+	})
+
+	// Remove workspace tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "remove_workspace",
+		Description: "Detach a workspace previously attached with add_workspace, shutting down its LSP client.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params RemoveWorkspaceParams) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		workspaceDir, err := filepath.Abs(params.WorkspaceDir)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to resolve workspace directory: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		toolLogger.Debug("executing tool", "tool", "remove_workspace", "workspace", workspaceDir)
+		if workspaceDir == s.config.workspaceDir {
+			toolLogger.Error("tool failed", "tool", "remove_workspace", "workspace", workspaceDir, "duration", time.Since(start), "error", "refusing to detach primary workspace")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "cannot remove the primary workspace: main.go's s.lspClient/s.workspaceWatcher and cleanup() are hardcoded to it, so closing its client would break definition/references/cleanup for the rest of the process. Restart the server to change the primary workspace instead."}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		if err := s.session.RemoveView(s.ctx, workspaceDir); err != nil {
+			toolLogger.Error("tool failed", "tool", "remove_workspace", "workspace", workspaceDir, "duration", time.Since(start), "error", err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to remove workspace: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+
+		toolLogger.Debug("tool completed", "tool", "remove_workspace", "workspace", workspaceDir, "duration", time.Since(start))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("detached workspace %s", workspaceDir)}},
+		}, nil, nil
+	})
+
+	// Cache stats tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "cache_stats",
+		Description: "Report definition cache hit/miss counts and per-workspace generation numbers, for debugging cache effectiveness.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, params struct{}) (*mcp.CallToolResult, any, error) {
+		stats := s.session.cache.Stats()
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(
+				"definition cache: %d hits, %d misses, %d entries\ngenerations: %v",
+				stats.DefinitionHits, stats.DefinitionMisses, stats.Entries, stats.Generations,
+			)}},
+		}, nil, nil
+	})
+
+	coreLogger.Info("successfully registered all MCP tools")
 	return nil
 }